@@ -0,0 +1,567 @@
+// Package asm implements a small two-pass assembler for LC-3 assembly
+// source, producing the big-endian object files that vm.LoadImage
+// loads (origin word followed by the program words).
+package asm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/lambdaclass/playground-vm-go/dbginfo"
+)
+
+// Opcodes, mirrored from the decoder in package vm.
+const (
+	opBR = iota
+	opADD
+	opLD
+	opST
+	opJSR
+	opAND
+	opLDR
+	opSTR
+	opRTI
+	opNOT
+	opLDI
+	opSTI
+	opJMP
+	opRES
+	opLEA
+	opTRAP
+)
+
+var trapCodes = map[string]uint16{
+	"GETC":  0x20,
+	"OUT":   0x21,
+	"PUTS":  0x22,
+	"IN":    0x23,
+	"PUTSP": 0x24,
+	"HALT":  0x25,
+}
+
+var brFlags = map[string]uint16{
+	"BR":    0x7,
+	"BRN":   0x4,
+	"BRZ":   0x2,
+	"BRP":   0x1,
+	"BRNZ":  0x6,
+	"BRNP":  0x5,
+	"BRZP":  0x3,
+	"BRNZP": 0x7,
+}
+
+// line is a parsed source line: optional label, optional
+// opcode/directive, and its raw operand fields.
+type line struct {
+	no       int
+	label    string
+	op       string
+	operands []string
+	addr     uint16 // filled in during pass one
+}
+
+// Assembler runs the two-pass assembly over a token stream.
+type Assembler struct {
+	origin  uint16
+	lines   []line
+	symbols map[string]uint16
+}
+
+// New creates an Assembler with an empty symbol table.
+func New() *Assembler {
+	return &Assembler{symbols: make(map[string]uint16)}
+}
+
+// Assemble reads LC-3 assembly from src and returns the object words,
+// with the origin address as the first element, ready to be written
+// big-endian via WriteObject.
+func Assemble(src io.Reader) ([]uint16, error) {
+	a := New()
+	if err := a.scan(src); err != nil {
+		return nil, err
+	}
+	if err := a.resolveSymbols(); err != nil {
+		return nil, err
+	}
+	return a.emit()
+}
+
+// AssembleWithDebug behaves like Assemble but additionally returns the
+// sidecar debug info for the program: every emitted address's source
+// line and the resolved label symbol table.
+func AssembleWithDebug(src io.Reader, filename string) ([]uint16, *dbginfo.Info, error) {
+	a := New()
+	if err := a.scan(src); err != nil {
+		return nil, nil, err
+	}
+	words, err := a.emit()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info := dbginfo.New()
+	for name, addr := range a.symbols {
+		info.Symbols = append(info.Symbols, dbginfo.Symbol{Name: name, Addr: addr})
+	}
+	for _, l := range a.lines {
+		for i := uint16(0); i < wordsFor(l); i++ {
+			entry := dbginfo.LineEntry{Addr: l.addr + i, File: filename, Line: l.no, Column: 1}
+			if i == 0 {
+				entry.Label = l.label
+			}
+			info.Lines = append(info.Lines, entry)
+		}
+	}
+	return words, info, nil
+}
+
+// scan is pass one: split source into lines, strip comments, and
+// compute the location counter for every label.
+func (a *Assembler) scan(src io.Reader) error {
+	scanner := bufio.NewScanner(src)
+	lc := uint16(0)
+	haveOrigin := false
+
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		raw := scanner.Text()
+		if idx := strings.IndexByte(raw, ';'); idx >= 0 {
+			raw = raw[:idx]
+		}
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		fields := tokenize(raw)
+		l := line{no: lineNo}
+
+		// A leading token that isn't a known mnemonic/directive is a label.
+		if !isMnemonic(fields[0]) {
+			l.label = strings.TrimSuffix(fields[0], ":")
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			if l.label != "" {
+				// label-only line: attaches to the next instruction's address
+				if err := a.defineSymbol(l.label, lc+a.origin, lineNo); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		l.op = strings.ToUpper(fields[0])
+		l.operands = fields[1:]
+
+		if l.op == ".ORIG" {
+			if haveOrigin {
+				return fmt.Errorf("asm: line %d: duplicate .ORIG", lineNo)
+			}
+			if err := needOperands(l, 1); err != nil {
+				return err
+			}
+			origin, err := parseImm(l.operands[0])
+			if err != nil {
+				return fmt.Errorf("asm: line %d: bad .ORIG operand: %w", lineNo, err)
+			}
+			a.origin = origin
+			haveOrigin = true
+			continue
+		}
+		if l.op == ".END" {
+			break
+		}
+		if !haveOrigin {
+			return fmt.Errorf("asm: line %d: instruction before .ORIG", lineNo)
+		}
+		if l.op == ".BLKW" || l.op == ".STRINGZ" {
+			if err := needOperands(l, 1); err != nil {
+				return err
+			}
+		}
+
+		l.addr = a.origin + lc
+		if l.label != "" {
+			if err := a.defineSymbol(l.label, l.addr, lineNo); err != nil {
+				return err
+			}
+		}
+
+		lc += wordsFor(l)
+		a.lines = append(a.lines, l)
+	}
+	if !haveOrigin {
+		return fmt.Errorf("asm: missing .ORIG directive")
+	}
+	return scanner.Err()
+}
+
+// defineSymbol records a label's address, rejecting a second
+// definition of the same label instead of silently letting it
+// overwrite the first (which would make any branch to it resolve to
+// whichever definition happened to come last).
+func (a *Assembler) defineSymbol(label string, addr uint16, lineNo int) error {
+	if _, ok := a.symbols[label]; ok {
+		return fmt.Errorf("asm: line %d: duplicate label %q", lineNo, label)
+	}
+	a.symbols[label] = addr
+	return nil
+}
+
+// resolveSymbols is a no-op placeholder: pass one already builds the
+// full symbol table, so pass two can resolve labels directly.
+func (a *Assembler) resolveSymbols() error {
+	return nil
+}
+
+// wordsFor returns how many 16-bit words a line occupies.
+func wordsFor(l line) uint16 {
+	switch l.op {
+	case ".BLKW":
+		n, err := parseImm(l.operands[0])
+		if err != nil {
+			return 1
+		}
+		return n
+	case ".STRINGZ":
+		s := unquote(l.operands[0])
+		return uint16(len(s) + 1) // +1 for the null terminator
+	default:
+		return 1
+	}
+}
+
+// emit is pass two: produce the final object words, origin first.
+func (a *Assembler) emit() ([]uint16, error) {
+	words := []uint16{a.origin}
+	for _, l := range a.lines {
+		out, err := a.assembleLine(l)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, out...)
+	}
+	return words, nil
+}
+
+func (a *Assembler) assembleLine(l line) ([]uint16, error) {
+	switch {
+	case l.op == ".FILL":
+		if err := needOperands(l, 1); err != nil {
+			return nil, err
+		}
+		v, err := a.value(l.operands[0], l.addr)
+		if err != nil {
+			return nil, err
+		}
+		return []uint16{v}, nil
+	case l.op == ".BLKW":
+		if err := needOperands(l, 1); err != nil {
+			return nil, err
+		}
+		n, err := parseImm(l.operands[0])
+		if err != nil {
+			return nil, fmt.Errorf("asm: line %d: %w", l.no, err)
+		}
+		return make([]uint16, n), nil
+	case l.op == ".STRINGZ":
+		if err := needOperands(l, 1); err != nil {
+			return nil, err
+		}
+		s := unquote(l.operands[0])
+		out := make([]uint16, 0, len(s)+1)
+		for _, r := range s {
+			out = append(out, uint16(r))
+		}
+		return append(out, 0), nil
+	case strings.HasPrefix(l.op, "BR"):
+		return a.assembleBR(l)
+	default:
+		instr, err := a.assembleOp(l)
+		if err != nil {
+			return nil, err
+		}
+		return []uint16{instr}, nil
+	}
+}
+
+func (a *Assembler) assembleBR(l line) ([]uint16, error) {
+	flags, ok := brFlags[l.op]
+	if !ok {
+		return nil, fmt.Errorf("asm: line %d: unknown branch mnemonic %s", l.no, l.op)
+	}
+	if err := needOperands(l, 1); err != nil {
+		return nil, err
+	}
+	off, err := a.pcOffset(l.operands[0], l.addr, 9)
+	if err != nil {
+		return nil, fmt.Errorf("asm: line %d: %w", l.no, err)
+	}
+	return []uint16{uint16(opBR)<<12 | flags<<9 | off}, nil
+}
+
+func (a *Assembler) assembleOp(l line) (uint16, error) {
+	switch l.op {
+	case "ADD", "AND":
+		if err := needOperands(l, 3); err != nil {
+			return 0, err
+		}
+		code := uint16(opADD)
+		if l.op == "AND" {
+			code = opAND
+		}
+		dr, err := reg(l.operands[0])
+		if err != nil {
+			return 0, a.lineErr(l, err)
+		}
+		sr1, err := reg(l.operands[1])
+		if err != nil {
+			return 0, a.lineErr(l, err)
+		}
+		if r2, err := reg(l.operands[2]); err == nil {
+			return code<<12 | dr<<9 | sr1<<6 | r2, nil
+		}
+		imm, err := parseImm(l.operands[2])
+		if err != nil {
+			return 0, a.lineErr(l, fmt.Errorf("bad operand %q", l.operands[2]))
+		}
+		return code<<12 | dr<<9 | sr1<<6 | 1<<5 | (imm & 0x1F), nil
+	case "NOT":
+		if err := needOperands(l, 2); err != nil {
+			return 0, err
+		}
+		dr, err := reg(l.operands[0])
+		if err != nil {
+			return 0, a.lineErr(l, err)
+		}
+		sr, err := reg(l.operands[1])
+		if err != nil {
+			return 0, a.lineErr(l, err)
+		}
+		return uint16(opNOT)<<12 | dr<<9 | sr<<6 | 0x3F, nil
+	case "JMP", "RET":
+		if l.op == "RET" {
+			return uint16(opJMP)<<12 | 7<<6, nil
+		}
+		if err := needOperands(l, 1); err != nil {
+			return 0, err
+		}
+		r1, err := reg(l.operands[0])
+		if err != nil {
+			return 0, a.lineErr(l, err)
+		}
+		return uint16(opJMP)<<12 | r1<<6, nil
+	case "JSR":
+		if err := needOperands(l, 1); err != nil {
+			return 0, err
+		}
+		off, err := a.pcOffset(l.operands[0], l.addr, 11)
+		if err != nil {
+			return 0, a.lineErr(l, err)
+		}
+		return uint16(opJSR)<<12 | 1<<11 | off, nil
+	case "JSRR":
+		if err := needOperands(l, 1); err != nil {
+			return 0, err
+		}
+		r1, err := reg(l.operands[0])
+		if err != nil {
+			return 0, a.lineErr(l, err)
+		}
+		return uint16(opJSR)<<12 | r1<<6, nil
+	case "LD", "LDI", "ST", "STI", "LEA":
+		if err := needOperands(l, 2); err != nil {
+			return 0, err
+		}
+		dr, err := reg(l.operands[0])
+		if err != nil {
+			return 0, a.lineErr(l, err)
+		}
+		off, err := a.pcOffset(l.operands[1], l.addr, 9)
+		if err != nil {
+			return 0, a.lineErr(l, err)
+		}
+		code := map[string]uint16{"LD": opLD, "LDI": opLDI, "ST": opST, "STI": opSTI, "LEA": opLEA}[l.op]
+		return code<<12 | dr<<9 | off, nil
+	case "LDR", "STR":
+		if err := needOperands(l, 3); err != nil {
+			return 0, err
+		}
+		dr, err := reg(l.operands[0])
+		if err != nil {
+			return 0, a.lineErr(l, err)
+		}
+		base, err := reg(l.operands[1])
+		if err != nil {
+			return 0, a.lineErr(l, err)
+		}
+		off, err := parseImm(l.operands[2])
+		if err != nil {
+			return 0, a.lineErr(l, err)
+		}
+		code := uint16(opLDR)
+		if l.op == "STR" {
+			code = opSTR
+		}
+		return code<<12 | dr<<9 | base<<6 | (off & 0x3F), nil
+	case "TRAP":
+		if err := needOperands(l, 1); err != nil {
+			return 0, err
+		}
+		code, err := parseImm(l.operands[0])
+		if err != nil {
+			return 0, a.lineErr(l, err)
+		}
+		return uint16(opTRAP)<<12 | (code & 0xFF), nil
+	case "GETC", "OUT", "PUTS", "IN", "PUTSP", "HALT":
+		return uint16(opTRAP)<<12 | trapCodes[l.op], nil
+	case "RTI":
+		return uint16(opRTI) << 12, nil
+	default:
+		return 0, fmt.Errorf("asm: line %d: unknown mnemonic %s", l.no, l.op)
+	}
+}
+
+func (a *Assembler) lineErr(l line, err error) error {
+	return fmt.Errorf("asm: line %d: %w", l.no, err)
+}
+
+// needOperands reports an error if l has fewer than n operands,
+// instead of letting a positional index panic further down.
+func needOperands(l line, n int) error {
+	if len(l.operands) < n {
+		return fmt.Errorf("asm: line %d: %s requires %d operand(s), got %d", l.no, l.op, n, len(l.operands))
+	}
+	return nil
+}
+
+// value resolves an operand that is either a label or an immediate,
+// used by .FILL.
+func (a *Assembler) value(operand string, _ uint16) (uint16, error) {
+	if addr, ok := a.symbols[operand]; ok {
+		return addr, nil
+	}
+	return parseImm(operand)
+}
+
+// pcOffset resolves a label or immediate operand to a PC-relative
+// offset, sign-extended to fit in bitCount bits of an instruction
+// field. PC is the address of the word *after* the instruction.
+func (a *Assembler) pcOffset(operand string, instrAddr uint16, bitCount uint) (uint16, error) {
+	var target uint16
+	if addr, ok := a.symbols[operand]; ok {
+		target = addr
+	} else {
+		imm, err := parseImm(operand)
+		if err != nil {
+			return 0, fmt.Errorf("undefined label %q", operand)
+		}
+		target = imm
+	}
+
+	offset := int32(target) - int32(instrAddr+1)
+	limit := int32(1) << (bitCount - 1)
+	if offset < -limit || offset >= limit {
+		return 0, fmt.Errorf("label %q out of range for %d-bit offset", operand, bitCount)
+	}
+	return uint16(offset) & ((1 << bitCount) - 1), nil
+}
+
+func reg(tok string) (uint16, error) {
+	tok = strings.ToUpper(tok)
+	if len(tok) == 2 && tok[0] == 'R' && tok[1] >= '0' && tok[1] <= '7' {
+		return uint16(tok[1] - '0'), nil
+	}
+	return 0, fmt.Errorf("not a register: %q", tok)
+}
+
+func parseImm(tok string) (uint16, error) {
+	tok = strings.TrimSuffix(tok, ",")
+	neg := false
+	switch {
+	case strings.HasPrefix(tok, "#-"):
+		neg = true
+		tok = "#" + tok[2:]
+	}
+	switch {
+	case strings.HasPrefix(tok, "#"):
+		n, err := strconv.ParseInt(tok[1:], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("bad decimal literal %q", tok)
+		}
+		if neg {
+			n = -n
+		}
+		return uint16(n), nil
+	case strings.HasPrefix(tok, "x") || strings.HasPrefix(tok, "X"):
+		n, err := strconv.ParseUint(tok[1:], 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("bad hex literal %q", tok)
+		}
+		return uint16(n), nil
+	default:
+		n, err := strconv.ParseInt(tok, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("bad literal %q", tok)
+		}
+		return uint16(n), nil
+	}
+}
+
+func unquote(tok string) string {
+	return strings.Trim(tok, `"`)
+}
+
+func tokenize(raw string) []string {
+	var fields []string
+	for _, f := range strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ','
+	}) {
+		fields = append(fields, f)
+	}
+	// .STRINGZ keeps its quoted argument intact even if it contained
+	// spaces (with or without a leading label); reassemble it from the
+	// raw operand portion.
+	for i := 0; i < len(fields) && i < 2; i++ {
+		if strings.EqualFold(fields[i], ".STRINGZ") {
+			if idx := strings.IndexByte(raw, '"'); idx >= 0 {
+				return append(fields[:i+1:i+1], raw[idx:])
+			}
+		}
+	}
+	return fields
+}
+
+var mnemonics = map[string]bool{
+	".ORIG": true, ".END": true, ".FILL": true, ".BLKW": true, ".STRINGZ": true,
+	"ADD": true, "AND": true, "NOT": true, "BR": true, "BRN": true, "BRZ": true,
+	"BRP": true, "BRNZ": true, "BRNP": true, "BRZP": true, "BRNZP": true,
+	"JMP": true, "RET": true, "JSR": true, "JSRR": true, "LD": true, "LDI": true,
+	"LDR": true, "LEA": true, "ST": true, "STI": true, "STR": true, "TRAP": true,
+	"RTI": true, "GETC": true, "OUT": true, "PUTS": true, "IN": true, "PUTSP": true,
+	"HALT": true,
+}
+
+func isMnemonic(tok string) bool {
+	return mnemonics[strings.ToUpper(tok)]
+}
+
+// WriteObject writes words to w as a big-endian LC-3 object file: the
+// origin address followed by every program word, matching the layout
+// vm.LoadImage expects.
+func WriteObject(w io.Writer, words []uint16) error {
+	buf := make([]byte, 2)
+	for _, word := range words {
+		buf[0] = byte(word >> 8)
+		buf[1] = byte(word)
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
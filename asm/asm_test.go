@@ -0,0 +1,144 @@
+package asm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lambdaclass/playground-vm-go/vm"
+)
+
+func TestAssembleMissingOperandsReturnsError(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+	}{
+		{"add missing operand", ".ORIG x3000\nADD R1, R2\n.END\n"},
+		{"not missing operand", ".ORIG x3000\nNOT R1\n.END\n"},
+		{"ld missing operand", ".ORIG x3000\nLD R1\n.END\n"},
+		{"ldr missing operand", ".ORIG x3000\nLDR R1, R2\n.END\n"},
+		{"trap missing operand", ".ORIG x3000\nTRAP\n.END\n"},
+		{"blkw missing operand", ".ORIG x3000\n.BLKW\n.END\n"},
+		{"orig missing operand", ".ORIG\n.END\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Assemble(strings.NewReader(c.src))
+			if err == nil {
+				t.Fatalf("expected an error, got nil")
+			}
+			if !strings.HasPrefix(err.Error(), "asm: line") {
+				t.Fatalf("expected a line-numbered asm error, got %q", err)
+			}
+		})
+	}
+}
+
+func TestAssembleDuplicateLabelReturnsError(t *testing.T) {
+	src := ".ORIG x3000\nFOO ADD R1, R2, R3\nFOO ADD R1, R2, R3\nBR FOO\n.END\n"
+	_, err := Assemble(strings.NewReader(src))
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+	if !strings.Contains(err.Error(), "duplicate label") {
+		t.Fatalf("expected a duplicate label error, got %q", err)
+	}
+}
+
+func TestAssembleBROffsetSignExtension(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want uint16
+	}{
+		{
+			// BR at x3000 (PC=x3001) to TARGET at x3002: offset +1.
+			name: "forward branch",
+			src:  ".ORIG x3000\nBR TARGET\nADD R0, R0, #0\nTARGET ADD R1, R1, #0\n.END\n",
+			want: uint16(opBR)<<12 | 7<<9 | 1,
+		},
+		{
+			// LOOP at x3000, BR at x3001 (PC=x3002) back to LOOP: offset -2.
+			name: "backward branch",
+			src:  ".ORIG x3000\nLOOP ADD R0, R0, #0\nBR LOOP\n.END\n",
+			want: uint16(opBR)<<12 | 7<<9 | (uint16(0xFFFE) & 0x1FF),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			words, err := Assemble(strings.NewReader(c.src))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var brWord uint16
+			for _, w := range words[1:] {
+				if w>>12 == uint16(opBR) {
+					brWord = w
+				}
+			}
+			if brWord != c.want {
+				t.Fatalf("expected BR word 0x%04X, got 0x%04X", c.want, brWord)
+			}
+		})
+	}
+}
+
+func TestAssembleBROffsetOutOfRangeReturnsError(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(".ORIG x3000\nBR TARGET\n")
+	for i := 0; i < 300; i++ {
+		b.WriteString("ADD R0, R0, #0\n")
+	}
+	b.WriteString("TARGET ADD R1, R1, #0\n.END\n")
+
+	_, err := Assemble(strings.NewReader(b.String()))
+	if err == nil {
+		t.Fatalf("expected an out-of-range offset error, got nil")
+	}
+	if !strings.Contains(err.Error(), "out of range") {
+		t.Fatalf("expected an out-of-range error, got %q", err)
+	}
+}
+
+func TestAssembleRoundTripThroughLoadImage(t *testing.T) {
+	src := ".ORIG x3000\nLEA R0, MSG\nTRAP x25\nMSG .STRINGZ \"hi\"\n.END\n"
+	words, err := Assemble(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteObject(&buf, words); err != nil {
+		t.Fatalf("WriteObject: %v", err)
+	}
+
+	theVM := vm.New()
+	if err := theVM.LoadImage(&buf); err != nil {
+		t.Fatalf("LoadImage: %v", err)
+	}
+
+	mem := theVM.Memory()
+	if mem[0x3000]>>12 != uint16(opLEA) {
+		t.Fatalf("expected LEA at 0x3000, got opcode 0x%X", mem[0x3000]>>12)
+	}
+	if mem[0x3001] != uint16(opTRAP)<<12|0x25 {
+		t.Fatalf("expected TRAP x25 at 0x3001, got 0x%04X", mem[0x3001])
+	}
+	if mem[0x3002] != 'h' || mem[0x3003] != 'i' || mem[0x3004] != 0 {
+		t.Fatalf("expected \"hi\\0\" at 0x3002, got %v", mem[0x3002:0x3005])
+	}
+}
+
+func TestAssembleValidProgram(t *testing.T) {
+	src := ".ORIG x3000\nADD R1, R2, R3\nADD R1, R2, #1\nHALT\n.END\n"
+	words, err := Assemble(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(words) != 4 {
+		t.Fatalf("expected origin + 3 instruction words, got %d", len(words))
+	}
+	if words[0] != 0x3000 {
+		t.Fatalf("expected origin 0x3000, got 0x%04X", words[0])
+	}
+}
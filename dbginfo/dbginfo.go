@@ -0,0 +1,218 @@
+// Package dbginfo reads and writes the sidecar debug-info file that
+// accompanies an assembled LC-3 object: a compact binary mapping from
+// each emitted address to its source location, plus the assembler's
+// label symbol table. It plays the same role as debug/gosym does for
+// Go binaries, scaled down to the LC-3's 16-bit address space.
+package dbginfo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// magic identifies the sidecar file format; version allows the
+// layout to change without breaking older files silently.
+var magic = [4]byte{'L', 'C', '3', 'D'}
+
+const version = 1
+
+// Symbol is an assembler label and the address it resolved to.
+type Symbol struct {
+	Name string
+	Addr uint16
+}
+
+// LineEntry maps one emitted address back to the source line that
+// produced it.
+type LineEntry struct {
+	Addr   uint16
+	File   string
+	Line   int
+	Column int
+	Label  string // label attached to this address, if any
+}
+
+// Info is the full sidecar debug-info table for one assembled
+// program.
+type Info struct {
+	Symbols []Symbol
+	Lines   []LineEntry
+}
+
+// New creates an empty Info ready to be populated by the assembler.
+func New() *Info {
+	return &Info{}
+}
+
+// SymbolAt returns the label defined at addr, if any.
+func (info *Info) SymbolAt(addr uint16) (string, bool) {
+	for _, s := range info.Symbols {
+		if s.Addr == addr {
+			return s.Name, true
+		}
+	}
+	return "", false
+}
+
+// AddrOf returns the address a label resolved to, if any.
+func (info *Info) AddrOf(name string) (uint16, bool) {
+	for _, s := range info.Symbols {
+		if s.Name == name {
+			return s.Addr, true
+		}
+	}
+	return 0, false
+}
+
+// LineAt returns the source location that emitted addr, if any.
+func (info *Info) LineAt(addr uint16) (LineEntry, bool) {
+	for _, l := range info.Lines {
+		if l.Addr == addr {
+			return l, true
+		}
+	}
+	return LineEntry{}, false
+}
+
+// Write serializes info to w as: magic, version, a symbol table
+// section, then a line table section.
+func Write(w io.Writer, info *Info) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(magic[:]); err != nil {
+		return err
+	}
+	if err := bw.WriteByte(version); err != nil {
+		return err
+	}
+
+	if err := binary.Write(bw, binary.BigEndian, uint32(len(info.Symbols))); err != nil {
+		return err
+	}
+	for _, s := range info.Symbols {
+		if err := writeEntry(bw, s.Addr, s.Name); err != nil {
+			return err
+		}
+	}
+
+	if err := binary.Write(bw, binary.BigEndian, uint32(len(info.Lines))); err != nil {
+		return err
+	}
+	for _, l := range info.Lines {
+		if err := binary.Write(bw, binary.BigEndian, l.Addr); err != nil {
+			return err
+		}
+		if err := writeString(bw, l.File); err != nil {
+			return err
+		}
+		if err := binary.Write(bw, binary.BigEndian, uint32(l.Line)); err != nil {
+			return err
+		}
+		if err := binary.Write(bw, binary.BigEndian, uint32(l.Column)); err != nil {
+			return err
+		}
+		if err := writeString(bw, l.Label); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+func writeEntry(w io.Writer, addr uint16, name string) error {
+	if err := binary.Write(w, binary.BigEndian, addr); err != nil {
+		return err
+	}
+	return writeString(w, name)
+}
+
+func writeString(w io.Writer, s string) error {
+	if err := binary.Write(w, binary.BigEndian, uint16(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+// Read parses a sidecar debug-info file written by Write.
+func Read(r io.Reader) (*Info, error) {
+	br := bufio.NewReader(r)
+
+	var got [4]byte
+	if _, err := io.ReadFull(br, got[:]); err != nil {
+		return nil, fmt.Errorf("dbginfo: %w", err)
+	}
+	if got != magic {
+		return nil, fmt.Errorf("dbginfo: bad magic %q", got)
+	}
+	v, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if v != version {
+		return nil, fmt.Errorf("dbginfo: unsupported version %d", v)
+	}
+
+	info := &Info{}
+
+	var symCount uint32
+	if err := binary.Read(br, binary.BigEndian, &symCount); err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < symCount; i++ {
+		var addr uint16
+		if err := binary.Read(br, binary.BigEndian, &addr); err != nil {
+			return nil, err
+		}
+		name, err := readString(br)
+		if err != nil {
+			return nil, err
+		}
+		info.Symbols = append(info.Symbols, Symbol{Name: name, Addr: addr})
+	}
+
+	var lineCount uint32
+	if err := binary.Read(br, binary.BigEndian, &lineCount); err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < lineCount; i++ {
+		var l LineEntry
+		if err := binary.Read(br, binary.BigEndian, &l.Addr); err != nil {
+			return nil, err
+		}
+		file, err := readString(br)
+		if err != nil {
+			return nil, err
+		}
+		l.File = file
+		var line, col uint32
+		if err := binary.Read(br, binary.BigEndian, &line); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(br, binary.BigEndian, &col); err != nil {
+			return nil, err
+		}
+		l.Line, l.Column = int(line), int(col)
+		label, err := readString(br)
+		if err != nil {
+			return nil, err
+		}
+		l.Label = label
+		info.Lines = append(info.Lines, l)
+	}
+
+	return info, nil
+}
+
+func readString(r io.Reader) (string, error) {
+	var n uint16
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
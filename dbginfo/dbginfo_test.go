@@ -0,0 +1,47 @@
+package dbginfo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	info := New()
+	info.Symbols = append(info.Symbols, Symbol{Name: "LOOP", Addr: 0x3001})
+	info.Lines = append(info.Lines, LineEntry{
+		Addr: 0x3001, File: "prog.asm", Line: 4, Column: 1, Label: "LOOP",
+	})
+
+	var buf bytes.Buffer
+	if err := Write(&buf, info); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	addr, ok := got.AddrOf("LOOP")
+	if !ok || addr != 0x3001 {
+		t.Fatalf("expected LOOP at 0x3001, got addr=0x%04X ok=%v", addr, ok)
+	}
+	name, ok := got.SymbolAt(0x3001)
+	if !ok || name != "LOOP" {
+		t.Fatalf("expected symbol LOOP at 0x3001, got name=%q ok=%v", name, ok)
+	}
+	line, ok := got.LineAt(0x3001)
+	if !ok {
+		t.Fatalf("expected a line entry at 0x3001")
+	}
+	if line.File != "prog.asm" || line.Line != 4 || line.Column != 1 || line.Label != "LOOP" {
+		t.Fatalf("line entry round-tripped incorrectly: %+v", line)
+	}
+}
+
+func TestReadRejectsBadMagic(t *testing.T) {
+	_, err := Read(bytes.NewReader([]byte("nope")))
+	if err == nil {
+		t.Fatalf("expected an error for bad magic, got nil")
+	}
+}
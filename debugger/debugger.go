@@ -0,0 +1,455 @@
+// Package debugger implements an interactive, line-based shell for
+// stepping through a running LC-3 program. It is wired into the VM's
+// fetch-execute loop via a hook that runs before every instruction.
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lambdaclass/playground-vm-go/disasm"
+	"github.com/lambdaclass/playground-vm-go/internal/tracelog"
+	"github.com/lambdaclass/playground-vm-go/vm"
+)
+
+// Register indices, mirrored from the VM's own layout.
+const (
+	numRegs = 10
+	rPC     = 8
+	rCOND   = 9
+)
+
+const historyCap = 32
+
+// step is a snapshot taken before one instruction executes: the
+// register file plus the memory words it changed, so "back" can
+// rewind without keeping a second full copy of memory around.
+type step struct {
+	regs  [numRegs]uint16
+	delta map[uint16]uint16
+}
+
+// Debugger holds breakpoints, watches and step history for one running
+// VM. It operates directly on the caller's memory and register arrays.
+type Debugger struct {
+	memory []uint16
+	regs   *[numRegs]uint16
+
+	in  *bufio.Reader
+	out io.Writer
+
+	breakpoints map[uint16]bool
+	watches     map[uint16]uint16 // addr -> last seen value
+	stepping    bool
+	history     []step
+	prevMem     []uint16
+	cmdHistory  []string
+
+	disasm *disasm.Disassembler // optional, enables symbolic disasm/list
+	vm     *vm.VM               // optional, enables save/load
+	ring   *tracelog.RingSink   // optional, enables the trace command
+
+	beforeRead func() // optional, restores canonical/echo mode for the prompt
+	afterRead  func() // optional, puts the terminal back in raw mode for the VM
+}
+
+// SetDisassembler attaches a symbolic disassembler (see package
+// disasm) so the disasm and list commands can resolve labels and
+// source lines instead of printing raw numeric operands.
+func (d *Debugger) SetDisassembler(da *disasm.Disassembler) {
+	d.disasm = da
+}
+
+// SetVM attaches the running VM so the save and load commands can
+// snapshot and restore its full state.
+func (d *Debugger) SetVM(v *vm.VM) {
+	d.vm = v
+}
+
+// SetRingSink attaches a ring buffer of recent trace events so the
+// trace command can show execution history leading up to the current
+// breakpoint, independent of whatever --log-level the user chose.
+func (d *Debugger) SetRingSink(r *tracelog.RingSink) {
+	d.ring = r
+}
+
+// SetLineEditing installs hooks the debugger calls around every
+// command prompt: beforeRead switches the terminal back to canonical,
+// echoing mode so typed commands are visible and backspace works, and
+// afterRead restores whatever raw mode the VM's own keyboard MMIO
+// needs. Both are optional; a caller that never put the terminal into
+// raw mode in the first place has no need for either.
+func (d *Debugger) SetLineEditing(beforeRead, afterRead func()) {
+	d.beforeRead = beforeRead
+	d.afterRead = afterRead
+}
+
+// New creates a Debugger over the given memory and register arrays.
+// in/out drive the interactive shell (typically os.Stdin/os.Stdout).
+func New(memory []uint16, regs *[numRegs]uint16, in io.Reader, out io.Writer) *Debugger {
+	d := &Debugger{
+		memory:      memory,
+		regs:        regs,
+		in:          bufio.NewReader(in),
+		out:         out,
+		breakpoints: make(map[uint16]bool),
+		watches:     make(map[uint16]uint16),
+		stepping:    true,
+	}
+	d.prevMem = append([]uint16(nil), memory...)
+	return d
+}
+
+// BeforeInstr is the hook the main fetch loop calls with the PC of the
+// instruction about to execute. It records history, reports watches
+// and breakpoints, and blocks on the shell until the user lets
+// execution continue.
+func (d *Debugger) BeforeInstr(pc uint16) {
+	if d.tracking() {
+		d.recordStep()
+	} else if len(d.history) > 0 {
+		// We were free-running with no breakpoints set, so nothing
+		// was being recorded; drop the now-stale history and resync
+		// the diff baseline rather than pay a full memory scan on
+		// every instruction just to keep it current.
+		d.history = nil
+		copy(d.prevMem, d.memory)
+	}
+	d.reportWatches()
+
+	if !d.stepping && !d.breakpoints[pc] {
+		return
+	}
+	if d.breakpoints[pc] {
+		fmt.Fprintf(d.out, "breakpoint hit at 0x%04X\n", pc)
+	}
+	d.stepping = true
+	d.repl(pc)
+}
+
+// tracking reports whether it's worth paying for per-instruction
+// history: either the user is single-stepping, or a breakpoint could
+// stop execution at any moment and "back" should be able to rewind
+// into the steps leading up to it.
+func (d *Debugger) tracking() bool {
+	return d.stepping || len(d.breakpoints) > 0
+}
+
+func (d *Debugger) recordStep() {
+	delta := make(map[uint16]uint16)
+	for addr, old := range d.prevMem {
+		if cur := d.memory[addr]; cur != old {
+			delta[uint16(addr)] = old
+		}
+	}
+	copy(d.prevMem, d.memory)
+
+	d.history = append(d.history, step{regs: *d.regs, delta: delta})
+	if len(d.history) > historyCap {
+		d.history = d.history[1:]
+	}
+}
+
+func (d *Debugger) reportWatches() {
+	for addr, last := range d.watches {
+		if cur := d.memory[addr]; cur != last {
+			fmt.Fprintf(d.out, "watch: 0x%04X changed 0x%04X -> 0x%04X\n", addr, last, cur)
+			d.watches[addr] = cur
+		}
+	}
+}
+
+// repl reads commands from d.in until one of them resumes execution
+// (step or continue).
+func (d *Debugger) repl(pc uint16) {
+	for {
+		fmt.Fprintf(d.out, "(lc3db 0x%04X) ", pc)
+		if d.beforeRead != nil {
+			d.beforeRead()
+		}
+		line, err := d.in.ReadString('\n')
+		if d.afterRead != nil {
+			d.afterRead()
+		}
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		d.cmdHistory = append(d.cmdHistory, strings.Join(fields, " "))
+
+		switch fields[0] {
+		case "step", "s":
+			d.stepping = true
+			return
+		case "continue", "c":
+			d.stepping = false
+			return
+		case "break", "b":
+			d.cmdBreak(fields[1:])
+		case "regs", "r":
+			d.cmdRegs()
+		case "mem", "m":
+			d.cmdMem(fields[1:])
+		case "disasm", "d":
+			d.cmdDisasm(fields[1:])
+		case "list", "l":
+			d.cmdList(fields[1:])
+		case "watch", "w":
+			d.cmdWatch(fields[1:])
+		case "back":
+			d.cmdBack()
+		case "save":
+			d.cmdSave(fields[1:])
+		case "load":
+			d.cmdLoad(fields[1:])
+		case "history":
+			d.cmdHistoryCmd()
+		case "trace":
+			d.cmdTrace()
+		default:
+			fmt.Fprintf(d.out, "unknown command %q\n", fields[0])
+		}
+	}
+}
+
+func (d *Debugger) cmdBreak(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(d.out, "usage: break <addr>")
+		return
+	}
+	addr, err := parseAddr(args[0])
+	if err != nil {
+		fmt.Fprintln(d.out, err)
+		return
+	}
+	d.breakpoints[addr] = true
+	fmt.Fprintf(d.out, "breakpoint set at 0x%04X\n", addr)
+}
+
+func (d *Debugger) cmdRegs() {
+	for i := 0; i < 8; i++ {
+		fmt.Fprintf(d.out, "R%d: 0x%04X  ", i, d.regs[i])
+	}
+	fmt.Fprintf(d.out, "\nPC: 0x%04X  COND: 0x%X\n", d.regs[rPC], d.regs[rCOND])
+}
+
+func (d *Debugger) cmdMem(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(d.out, "usage: mem <addr> [len]")
+		return
+	}
+	addr, err := parseAddr(args[0])
+	if err != nil {
+		fmt.Fprintln(d.out, err)
+		return
+	}
+	if int(addr) >= len(d.memory) {
+		fmt.Fprintf(d.out, "address 0x%04X is out of range (memory size %d)\n", addr, len(d.memory))
+		return
+	}
+	length := 1
+	if len(args) > 1 {
+		if n, err := strconv.Atoi(args[1]); err == nil {
+			length = n
+		}
+	}
+	if max := len(d.memory) - int(addr); length > max {
+		length = max
+	}
+	for i := 0; i < length; i++ {
+		fmt.Fprintf(d.out, "0x%04X: 0x%04X\n", int(addr)+i, d.memory[int(addr)+i])
+	}
+}
+
+func (d *Debugger) cmdDisasm(args []string) {
+	addr := d.regs[rPC]
+	n := 1
+	if len(args) > 0 {
+		if a, err := parseAddr(args[0]); err == nil {
+			addr = a
+		}
+	}
+	if len(args) > 1 {
+		if v, err := strconv.Atoi(args[1]); err == nil {
+			n = v
+		}
+	}
+	for i := 0; i < n; i++ {
+		fmt.Fprintln(d.out, d.decode(addr))
+		addr++
+	}
+}
+
+// cmdList shows the disassembly around the current PC, the same way
+// disasm does for a single address but centered on a small window.
+func (d *Debugger) cmdList(args []string) {
+	addr := d.regs[rPC]
+	const window = 5
+	start := addr
+	if start > window {
+		start -= window
+	} else {
+		start = 0
+	}
+	for i := uint16(0); i <= 2*window; i++ {
+		cur := start + i
+		marker := "  "
+		if cur == addr {
+			marker = "->"
+		}
+		fmt.Fprintf(d.out, "%s %s\n", marker, d.decode(cur))
+	}
+}
+
+// decode renders one instruction, using the symbolic disassembler
+// when one has been attached, or a bare mnemonic decode otherwise.
+func (d *Debugger) decode(addr uint16) string {
+	if d.disasm != nil {
+		return d.disasm.Decode(addr)
+	}
+	instr := d.memory[addr]
+	return fmt.Sprintf("0x%04X: 0x%04X  %s", addr, instr, bareDecode(instr))
+}
+
+func (d *Debugger) cmdWatch(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(d.out, "usage: watch <addr>")
+		return
+	}
+	addr, err := parseAddr(args[0])
+	if err != nil {
+		fmt.Fprintln(d.out, err)
+		return
+	}
+	d.watches[addr] = d.memory[addr]
+	fmt.Fprintf(d.out, "watching 0x%04X\n", addr)
+}
+
+// cmdBack rewinds one step by restoring the previous register file
+// and undoing any memory writes that step made.
+func (d *Debugger) cmdBack() {
+	if len(d.history) == 0 {
+		fmt.Fprintln(d.out, "no history to rewind")
+		return
+	}
+	last := d.history[len(d.history)-1]
+	d.history = d.history[:len(d.history)-1]
+
+	*d.regs = last.regs
+	for addr, old := range last.delta {
+		d.memory[addr] = old
+	}
+	copy(d.prevMem, d.memory)
+	fmt.Fprintf(d.out, "rewound to PC 0x%04X\n", d.regs[rPC])
+}
+
+// cmdSave writes the attached VM's full state to a snapshot file. It
+// has no effect if no VM has been attached via SetVM.
+func (d *Debugger) cmdSave(args []string) {
+	if d.vm == nil {
+		fmt.Fprintln(d.out, "no VM attached, cannot save")
+		return
+	}
+	if len(args) != 1 {
+		fmt.Fprintln(d.out, "usage: save <path>")
+		return
+	}
+	f, err := os.Create(args[0])
+	if err != nil {
+		fmt.Fprintln(d.out, err)
+		return
+	}
+	defer f.Close()
+	if err := d.vm.SaveState(f, "saved from debugger"); err != nil {
+		fmt.Fprintln(d.out, err)
+		return
+	}
+	fmt.Fprintf(d.out, "saved state to %s\n", args[0])
+}
+
+// cmdLoad restores the attached VM's full state from a snapshot file
+// written by cmdSave. Since the debugger's memory and register views
+// share their backing storage with the VM, the restored state is
+// visible immediately.
+func (d *Debugger) cmdLoad(args []string) {
+	if d.vm == nil {
+		fmt.Fprintln(d.out, "no VM attached, cannot load")
+		return
+	}
+	if len(args) != 1 {
+		fmt.Fprintln(d.out, "usage: load <path>")
+		return
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		fmt.Fprintln(d.out, err)
+		return
+	}
+	defer f.Close()
+	if err := d.vm.LoadState(f); err != nil {
+		fmt.Fprintln(d.out, err)
+		return
+	}
+	copy(d.prevMem, d.memory)
+	fmt.Fprintf(d.out, "loaded state from %s\n", args[0])
+}
+
+// cmdHistoryCmd lists commands entered earlier in this session, since
+// the prompt itself has no readline-style recall.
+func (d *Debugger) cmdHistoryCmd() {
+	if len(d.cmdHistory) == 0 {
+		fmt.Fprintln(d.out, "no command history yet")
+		return
+	}
+	for i, cmd := range d.cmdHistory {
+		fmt.Fprintf(d.out, "%4d  %s\n", i+1, cmd)
+	}
+}
+
+// cmdTrace prints the buffered execution history from the attached
+// ring sink, in the same format textSink uses for --log-file/stderr
+// output. It has no effect if no ring sink has been attached via
+// SetRingSink.
+func (d *Debugger) cmdTrace() {
+	if d.ring == nil {
+		fmt.Fprintln(d.out, "no trace history attached")
+		return
+	}
+	events := d.ring.Events()
+	if len(events) == 0 {
+		fmt.Fprintln(d.out, "no trace history yet")
+		return
+	}
+	for _, ev := range events {
+		fmt.Fprintf(d.out, "[%s] %-5s pc=0x%04X %-5s %s %s\n", ev.Level, ev.Category, ev.PC, ev.Opcode, ev.Operands, ev.Detail)
+	}
+}
+
+func parseAddr(tok string) (uint16, error) {
+	tok = strings.TrimPrefix(strings.TrimPrefix(tok, "0x"), "x")
+	n, err := strconv.ParseUint(tok, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("bad address %q", tok)
+	}
+	return uint16(n), nil
+}
+
+var opcodeNames = [16]string{
+	"BR", "ADD", "LD", "ST", "JSR", "AND", "LDR", "STR",
+	"RTI", "NOT", "LDI", "STI", "JMP", "RES", "LEA", "TRAP",
+}
+
+// bareDecode does a bare, non-symbolic decode of one instruction word
+// (mnemonic plus raw operand fields), used when no debug info has
+// been attached for label resolution.
+func bareDecode(instr uint16) string {
+	op := instr >> 12
+	return fmt.Sprintf("%-4s operands=0x%03X", opcodeNames[op], instr&0xFFF)
+}
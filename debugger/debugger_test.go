@@ -0,0 +1,92 @@
+package debugger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lambdaclass/playground-vm-go/internal/tracelog"
+)
+
+func newTestDebugger(in string) (*Debugger, *bytes.Buffer) {
+	memory := make([]uint16, 16)
+	var regs [numRegs]uint16
+	out := new(bytes.Buffer)
+	d := New(memory, &regs, strings.NewReader(in), out)
+	return d, out
+}
+
+func TestBeforeInstrSkipsHistoryWhileFreeRunning(t *testing.T) {
+	d, _ := newTestDebugger("continue\n")
+	d.BeforeInstr(0) // enters the repl, reads "continue", sets stepping=false
+
+	for pc := uint16(1); pc < 10; pc++ {
+		d.BeforeInstr(pc)
+	}
+	if len(d.history) != 0 {
+		t.Fatalf("expected no history while continuing with no breakpoints, got %d entries", len(d.history))
+	}
+}
+
+func TestBeforeInstrRecordsHistoryWithBreakpointSet(t *testing.T) {
+	d, _ := newTestDebugger("break x5\ncontinue\n")
+	d.BeforeInstr(0) // reads "break x5" then "continue"
+
+	d.memory[1] = 0x1111
+	d.BeforeInstr(1)
+	if len(d.history) != 2 {
+		t.Fatalf("expected history to accumulate once a breakpoint is set, got %d entries", len(d.history))
+	}
+}
+
+func TestCommandHistoryRecordsTypedCommands(t *testing.T) {
+	d, out := newTestDebugger("regs\nhistory\ncontinue\n")
+	d.BeforeInstr(0)
+
+	got := out.String()
+	if !strings.Contains(got, "1  regs") {
+		t.Fatalf("expected command history to list prior commands, got:\n%s", got)
+	}
+}
+
+func TestCmdMemClampsLengthToMemorySize(t *testing.T) {
+	d, out := newTestDebugger("mem 0 20\ncontinue\n")
+	d.BeforeInstr(0) // must not panic even though 0+20 exceeds the 16-word test memory
+
+	lines := strings.Count(out.String(), ": 0x")
+	if lines != len(d.memory) {
+		t.Fatalf("expected mem to print exactly %d lines (clamped to memory size), got %d", len(d.memory), lines)
+	}
+}
+
+func TestCmdMemRejectsOutOfRangeAddr(t *testing.T) {
+	d, out := newTestDebugger("mem 0x10 1\ncontinue\n")
+	d.BeforeInstr(0) // addr 0x10 is already past the 16-word test memory
+
+	if !strings.Contains(out.String(), "out of range") {
+		t.Fatalf("expected an out-of-range message, got:\n%s", out.String())
+	}
+}
+
+func TestCmdTraceWithoutRingSink(t *testing.T) {
+	d, out := newTestDebugger("trace\ncontinue\n")
+	d.BeforeInstr(0)
+
+	if !strings.Contains(out.String(), "no trace history attached") {
+		t.Fatalf("expected a no-ring-sink message, got:\n%s", out.String())
+	}
+}
+
+func TestCmdTracePrintsBufferedEvents(t *testing.T) {
+	d, out := newTestDebugger("trace\ncontinue\n")
+	ring := tracelog.NewRingSink(4)
+	ring.Write(tracelog.Event{Level: tracelog.DEBUG, Category: tracelog.Fetch, PC: 0x3000, Opcode: "ADD"})
+	d.SetRingSink(ring)
+
+	d.BeforeInstr(0)
+
+	got := out.String()
+	if !strings.Contains(got, "pc=0x3000") || !strings.Contains(got, "ADD") {
+		t.Fatalf("expected trace output to include the buffered event, got:\n%s", got)
+	}
+}
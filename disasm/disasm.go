@@ -0,0 +1,102 @@
+// Package disasm decodes LC-3 instruction words into readable
+// mnemonics. When sidecar debug info (see dbginfo) is available for
+// the running image, PC-relative operands resolve to label names and
+// each line is annotated with its originating source location.
+package disasm
+
+import (
+	"fmt"
+
+	"github.com/lambdaclass/playground-vm-go/dbginfo"
+)
+
+var opcodeNames = [16]string{
+	"BR", "ADD", "LD", "ST", "JSR", "AND", "LDR", "STR",
+	"RTI", "NOT", "LDI", "STI", "JMP", "RES", "LEA", "TRAP",
+}
+
+// Disassembler decodes words out of a live VM memory image, resolving
+// symbols through an optional Info table.
+type Disassembler struct {
+	memory []uint16
+	info   *dbginfo.Info
+}
+
+// New creates a Disassembler over memory. info may be nil, in which
+// case operands are decoded numerically with no symbol resolution.
+func New(memory []uint16, info *dbginfo.Info) *Disassembler {
+	return &Disassembler{memory: memory, info: info}
+}
+
+// Decode returns a one-line rendering of the instruction at pc:
+// mnemonic, symbolic or numeric operands, and the source line that
+// produced it when debug info is available.
+func (d *Disassembler) Decode(pc uint16) string {
+	instr := d.memory[pc]
+	op := instr >> 12
+	body := d.operands(op, instr, pc)
+
+	line := fmt.Sprintf("0x%04X: 0x%04X  %-4s %s", pc, instr, opcodeNames[op], body)
+	if d.info != nil {
+		if l, ok := d.info.LineAt(pc); ok {
+			line += fmt.Sprintf("  ; %s:%d", l.File, l.Line)
+		}
+	}
+	return line
+}
+
+func (d *Disassembler) operands(op, instr, pc uint16) string {
+	switch op {
+	case 0: // BR
+		return fmt.Sprintf("nzp=0x%X %s", (instr>>9)&0x7, d.target(pc, instr&0x1FF, 9))
+	case 1, 5: // ADD, AND
+		dr, sr1 := (instr>>9)&0x7, (instr>>6)&0x7
+		if (instr>>5)&1 == 1 {
+			return fmt.Sprintf("R%d, R%d, #%d", dr, sr1, signExtend(instr&0x1F, 5))
+		}
+		return fmt.Sprintf("R%d, R%d, R%d", dr, sr1, instr&0x7)
+	case 9: // NOT
+		return fmt.Sprintf("R%d, R%d", (instr>>9)&0x7, (instr>>6)&0x7)
+	case 2, 10, 14: // LD, LDI, LEA
+		return fmt.Sprintf("R%d, %s", (instr>>9)&0x7, d.target(pc, instr&0x1FF, 9))
+	case 3, 11: // ST, STI
+		return fmt.Sprintf("R%d, %s", (instr>>9)&0x7, d.target(pc, instr&0x1FF, 9))
+	case 6, 7: // LDR, STR
+		return fmt.Sprintf("R%d, R%d, #%d", (instr>>9)&0x7, (instr>>6)&0x7, signExtend(instr&0x3F, 6))
+	case 4: // JSR / JSRR
+		if (instr>>11)&1 == 1 {
+			return d.target(pc, instr&0x7FF, 11)
+		}
+		return fmt.Sprintf("R%d", (instr>>6)&0x7)
+	case 12: // JMP / RET
+		r1 := (instr >> 6) & 0x7
+		if r1 == 7 {
+			return "RET"
+		}
+		return fmt.Sprintf("R%d", r1)
+	case 15: // TRAP
+		return fmt.Sprintf("0x%02X", instr&0xFF)
+	default:
+		return fmt.Sprintf("0x%03X", instr&0xFFF)
+	}
+}
+
+// target resolves a PC-relative field to a symbolic label when debug
+// info has one at that address, falling back to the raw address.
+func (d *Disassembler) target(pc, field, bitCount uint16) string {
+	offset := signExtend(field, int(bitCount))
+	addr := pc + 1 + offset
+	if d.info != nil {
+		if name, ok := d.info.SymbolAt(addr); ok {
+			return name
+		}
+	}
+	return fmt.Sprintf("0x%04X", addr)
+}
+
+func signExtend(x uint16, bitCount int) uint16 {
+	if (x>>(bitCount-1))&1 == 1 {
+		x |= 0xFFFF << bitCount
+	}
+	return x
+}
@@ -0,0 +1,36 @@
+package disasm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lambdaclass/playground-vm-go/dbginfo"
+)
+
+func TestDecodeResolvesLabelThroughDebugInfo(t *testing.T) {
+	// BR to 0x3002, i.e. offset +0 from the word after 0x3001.
+	brInstr := uint16(0)<<12 | 0x7<<9 | (0 & 0x1FF)
+	memory := make([]uint16, 0x3003)
+	memory[0x3001] = brInstr
+
+	info := dbginfo.New()
+	info.Symbols = append(info.Symbols, dbginfo.Symbol{Name: "LOOP", Addr: 0x3002})
+
+	d := New(memory, info)
+	line := d.Decode(0x3001)
+	if !strings.Contains(line, "LOOP") {
+		t.Fatalf("expected decoded BR to resolve target to label LOOP, got: %q", line)
+	}
+}
+
+func TestDecodeFallsBackToAddressWithoutDebugInfo(t *testing.T) {
+	brInstr := uint16(0)<<12 | 0x7<<9 | (0 & 0x1FF)
+	memory := make([]uint16, 0x3003)
+	memory[0x3001] = brInstr
+
+	d := New(memory, nil)
+	line := d.Decode(0x3001)
+	if !strings.Contains(line, "0x3002") {
+		t.Fatalf("expected decoded BR to show raw target address, got: %q", line)
+	}
+}
@@ -0,0 +1,220 @@
+// Package tracelog is a small leveled logger for the VM's execution
+// events (fetch, memory access, trap dispatch, I/O), replacing the
+// ad-hoc fmt.Println calls that used to be scattered through the
+// fetch loop and opcode handlers. Events can be filtered by level and
+// by category and fanned out to several sinks at once.
+package tracelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Level orders trace verbosity from silent to everything.
+type Level int
+
+const (
+	OFF Level = iota
+	ERROR
+	INFO
+	DEBUG
+	TRACE
+)
+
+// ParseLevel maps a --log-level flag value to a Level.
+func ParseLevel(s string) (Level, error) {
+	switch s {
+	case "off":
+		return OFF, nil
+	case "error":
+		return ERROR, nil
+	case "info":
+		return INFO, nil
+	case "debug":
+		return DEBUG, nil
+	case "trace":
+		return TRACE, nil
+	default:
+		return OFF, fmt.Errorf("tracelog: unknown level %q", s)
+	}
+}
+
+func (l Level) String() string {
+	switch l {
+	case OFF:
+		return "OFF"
+	case ERROR:
+		return "ERROR"
+	case INFO:
+		return "INFO"
+	case DEBUG:
+		return "DEBUG"
+	case TRACE:
+		return "TRACE"
+	default:
+		return "?"
+	}
+}
+
+// Category groups events by the part of the VM that produced them.
+type Category string
+
+const (
+	Fetch Category = "fetch"
+	Mem   Category = "mem"
+	Trap  Category = "trap"
+	IO    Category = "io"
+)
+
+// Event is one traced execution step.
+type Event struct {
+	Level    Level
+	Category Category
+	PC       uint16
+	Opcode   string
+	Operands string
+	Detail   string // e.g. a register or memory mutation
+}
+
+// Sink receives every event that passes the Logger's filters.
+type Sink interface {
+	Write(Event)
+}
+
+// Logger fans filtered events out to its sinks. The zero Logger is
+// usable and discards everything (level OFF).
+type Logger struct {
+	mu         sync.Mutex
+	level      Level
+	categories map[Category]bool // nil means all categories enabled
+	sinks      []Sink
+}
+
+// New creates a Logger at the given level writing to sinks.
+func New(level Level, sinks ...Sink) *Logger {
+	return &Logger{level: level, sinks: sinks}
+}
+
+// SetCategories restricts tracing to the given categories; an empty
+// call re-enables every category.
+func (l *Logger) SetCategories(cats ...Category) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(cats) == 0 {
+		l.categories = nil
+		return
+	}
+	l.categories = make(map[Category]bool, len(cats))
+	for _, c := range cats {
+		l.categories[c] = true
+	}
+}
+
+// AddSink appends another destination for events.
+func (l *Logger) AddSink(s Sink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks, s)
+}
+
+// Log records one event if it passes the level and category filters.
+func (l *Logger) Log(ev Event) {
+	if l == nil || ev.Level > l.level || l.level == OFF {
+		return
+	}
+	if l.categories != nil && !l.categories[ev.Category] {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, s := range l.sinks {
+		s.Write(ev)
+	}
+}
+
+// textSink writes human-readable lines, optionally colored with ANSI
+// escapes, to an io.Writer (typically os.Stderr).
+type textSink struct {
+	w     io.Writer
+	color bool
+}
+
+// NewTextSink creates a sink that formats events as plain text lines.
+func NewTextSink(w io.Writer, color bool) Sink {
+	return &textSink{w: w, color: color}
+}
+
+func (s *textSink) Write(ev Event) {
+	line := fmt.Sprintf("[%s] %-5s pc=0x%04X %-5s %s %s", ev.Level, ev.Category, ev.PC, ev.Opcode, ev.Operands, ev.Detail)
+	if s.color {
+		line = colorFor(ev.Level) + line + ansiReset
+	}
+	fmt.Fprintln(s.w, line)
+}
+
+const ansiReset = "\x1b[0m"
+
+func colorFor(l Level) string {
+	switch l {
+	case ERROR:
+		return "\x1b[31m" // red
+	case INFO:
+		return "\x1b[36m" // cyan
+	case DEBUG:
+		return "\x1b[33m" // yellow
+	case TRACE:
+		return "\x1b[90m" // gray
+	default:
+		return ""
+	}
+}
+
+// jsonSink writes one JSON object per line (JSON Lines).
+type jsonSink struct {
+	enc *json.Encoder
+}
+
+// NewJSONSink creates a sink that writes newline-delimited JSON
+// events to w (typically an open log file).
+func NewJSONSink(w io.Writer) Sink {
+	return &jsonSink{enc: json.NewEncoder(w)}
+}
+
+func (s *jsonSink) Write(ev Event) {
+	_ = s.enc.Encode(ev)
+}
+
+// RingSink keeps the last N events in memory, for surfacing trace
+// history to the interactive debugger without re-running the program.
+type RingSink struct {
+	mu     sync.Mutex
+	events []Event
+	cap    int
+}
+
+// NewRingSink creates a ring buffer sink holding at most capacity
+// events.
+func NewRingSink(capacity int) *RingSink {
+	return &RingSink{cap: capacity}
+}
+
+func (s *RingSink) Write(ev Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, ev)
+	if len(s.events) > s.cap {
+		s.events = s.events[len(s.events)-s.cap:]
+	}
+}
+
+// Events returns a copy of the events currently buffered, oldest
+// first.
+func (s *RingSink) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Event, len(s.events))
+	copy(out, s.events)
+	return out
+}
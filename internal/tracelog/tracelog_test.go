@@ -0,0 +1,71 @@
+package tracelog
+
+import "testing"
+
+type recordingSink struct {
+	events []Event
+}
+
+func (s *recordingSink) Write(ev Event) {
+	s.events = append(s.events, ev)
+}
+
+func TestLogFiltersByLevel(t *testing.T) {
+	sink := &recordingSink{}
+	l := New(INFO, sink)
+
+	l.Log(Event{Level: ERROR, Category: Fetch})
+	l.Log(Event{Level: INFO, Category: Fetch})
+	l.Log(Event{Level: DEBUG, Category: Fetch})
+	l.Log(Event{Level: TRACE, Category: Fetch})
+
+	if len(sink.events) != 2 {
+		t.Fatalf("expected ERROR and INFO to pass an INFO-level logger, got %d events", len(sink.events))
+	}
+}
+
+func TestLogOffDiscardsEverything(t *testing.T) {
+	sink := &recordingSink{}
+	l := New(OFF, sink)
+
+	l.Log(Event{Level: ERROR, Category: Fetch})
+
+	if len(sink.events) != 0 {
+		t.Fatalf("expected OFF to discard all events, got %d", len(sink.events))
+	}
+}
+
+func TestSetCategoriesFiltersEvents(t *testing.T) {
+	sink := &recordingSink{}
+	l := New(TRACE, sink)
+	l.SetCategories(Trap, IO)
+
+	l.Log(Event{Level: TRACE, Category: Fetch})
+	l.Log(Event{Level: TRACE, Category: Trap})
+	l.Log(Event{Level: TRACE, Category: IO})
+
+	if len(sink.events) != 2 {
+		t.Fatalf("expected only Trap and IO events to pass, got %d", len(sink.events))
+	}
+
+	l.SetCategories()
+	l.Log(Event{Level: TRACE, Category: Fetch})
+	if len(sink.events) != 3 {
+		t.Fatalf("expected an empty SetCategories call to re-enable all categories, got %d events", len(sink.events))
+	}
+}
+
+func TestRingSinkEvictsOldestBeyondCapacity(t *testing.T) {
+	r := NewRingSink(2)
+	r.Write(Event{PC: 1})
+	r.Write(Event{PC: 2})
+	r.Write(Event{PC: 3})
+
+	events := r.Events()
+	if len(events) != 2 {
+		t.Fatalf("expected the ring to hold at most 2 events, got %d", len(events))
+	}
+	if events[0].PC != 2 || events[1].PC != 3 {
+		t.Fatalf("expected the oldest event to be evicted first, got %+v", events)
+	}
+}
@@ -0,0 +1,213 @@
+package vm
+
+import (
+	"bufio"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Snapshot file format: magic, version, then three TLV sections
+// (4-byte tag + uint32 length + payload) in a fixed order. MEM is
+// zlib-compressed, which collapses the long zero runs a mostly-empty
+// 64K address space is full of.
+var stateMagic = [4]byte{'L', 'C', '3', 'S'}
+
+const stateVersion = 1
+
+const (
+	tagREGS = "REGS"
+	tagMEM  = "MEM\x00"
+	tagMETA = "META"
+)
+
+// SaveState serializes memory, registers (including PC and COND), and
+// the running flag to w as a versioned, portable snapshot. notes is
+// free-form text stored alongside the snapshot's timestamp.
+func (v *VM) SaveState(w io.Writer, notes string) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(stateMagic[:]); err != nil {
+		return err
+	}
+	if err := bw.WriteByte(stateVersion); err != nil {
+		return err
+	}
+
+	if err := writeSection(bw, tagREGS, v.encodeRegs()); err != nil {
+		return err
+	}
+
+	memPayload, err := v.encodeMem()
+	if err != nil {
+		return err
+	}
+	if err := writeSection(bw, tagMEM, memPayload); err != nil {
+		return err
+	}
+
+	if err := writeSection(bw, tagMETA, encodeMeta(notes)); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// LoadState restores memory, registers and the running flag from a
+// snapshot written by SaveState, replacing the VM's current state.
+func (v *VM) LoadState(r io.Reader) error {
+	br := bufio.NewReader(r)
+
+	var got [4]byte
+	if _, err := io.ReadFull(br, got[:]); err != nil {
+		return fmt.Errorf("vm: %w", err)
+	}
+	if got != stateMagic {
+		return fmt.Errorf("vm: bad snapshot magic %q", got)
+	}
+	ver, err := br.ReadByte()
+	if err != nil {
+		return err
+	}
+	if ver != stateVersion {
+		return fmt.Errorf("vm: unsupported snapshot version %d", ver)
+	}
+
+	for {
+		tag, payload, err := readSection(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch tag {
+		case tagREGS:
+			if err := v.decodeRegs(payload); err != nil {
+				return err
+			}
+		case tagMEM:
+			if err := v.decodeMem(payload); err != nil {
+				return err
+			}
+		case tagMETA:
+			// timestamp/notes are informational only; nothing to restore.
+		default:
+			return fmt.Errorf("vm: unknown snapshot section %q", tag)
+		}
+	}
+	return nil
+}
+
+func writeSection(w io.Writer, tag string, payload []byte) error {
+	if _, err := io.WriteString(w, tag); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(payload))); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+func readSection(r io.Reader) (tag string, payload []byte, err error) {
+	tagBuf := make([]byte, 4)
+	if _, err := io.ReadFull(r, tagBuf); err != nil {
+		return "", nil, err
+	}
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return "", nil, err
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return "", nil, err
+	}
+	return string(tagBuf), payload, nil
+}
+
+func (v *VM) encodeRegs() []byte {
+	buf := make([]byte, 0, rCOUNT*2+1)
+	for _, r := range v.reg {
+		buf = binary.BigEndian.AppendUint16(buf, r)
+	}
+	if v.running {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+func (v *VM) decodeRegs(payload []byte) error {
+	if len(payload) != rCOUNT*2+1 {
+		return fmt.Errorf("vm: malformed REGS section (%d bytes)", len(payload))
+	}
+	for i := range v.reg {
+		v.reg[i] = binary.BigEndian.Uint16(payload[i*2:])
+	}
+	v.running = payload[rCOUNT*2] != 0
+	v.primed = true
+	return nil
+}
+
+func (v *VM) encodeMem() ([]byte, error) {
+	var buf bufferWriter
+	zw := zlib.NewWriter(&buf)
+	for _, word := range v.memory {
+		if err := binary.Write(zw, binary.BigEndian, word); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.b, nil
+}
+
+func (v *VM) decodeMem(payload []byte) error {
+	zr, err := zlib.NewReader(&bufferReader{b: payload})
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	words := make([]uint16, len(v.memory))
+	if err := binary.Read(zr, binary.BigEndian, &words); err != nil {
+		return fmt.Errorf("vm: malformed MEM section: %w", err)
+	}
+	copy(v.memory, words)
+	return nil
+}
+
+func encodeMeta(notes string) []byte {
+	buf := make([]byte, 0, 8+2+len(notes))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(time.Now().Unix()))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(notes)))
+	buf = append(buf, notes...)
+	return buf
+}
+
+// bufferWriter/bufferReader avoid pulling in bytes.Buffer just to
+// satisfy io.Writer/io.Reader around a growable byte slice.
+type bufferWriter struct{ b []byte }
+
+func (w *bufferWriter) Write(p []byte) (int, error) {
+	w.b = append(w.b, p...)
+	return len(p), nil
+}
+
+type bufferReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *bufferReader) Read(p []byte) (int, error) {
+	if r.pos >= len(r.b) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.b[r.pos:])
+	r.pos += n
+	return n, nil
+}
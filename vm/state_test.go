@@ -0,0 +1,67 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestLoadStateRoundTrip(t *testing.T) {
+	haltInstr := uint16(opTRAP)<<12 | trapHALT
+	v := New()
+	if err := v.LoadImage(image(0x3000, haltInstr)); err != nil {
+		t.Fatalf("LoadImage: %v", err)
+	}
+	v.reg[rR0] = 0x1234
+	v.reg[rPC] = 0x3000
+
+	var snap bytes.Buffer
+	if err := v.SaveState(&snap, "unit test"); err != nil {
+		t.Fatalf("SaveState: %v", err)
+	}
+
+	restored := New()
+	if err := restored.LoadState(bytes.NewReader(snap.Bytes())); err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if restored.reg[rR0] != 0x1234 {
+		t.Fatalf("expected R0 0x1234, got 0x%04X", restored.reg[rR0])
+	}
+	if restored.Memory()[0x3000] != haltInstr {
+		t.Fatalf("expected memory at 0x3000 to round-trip, got 0x%04X", restored.Memory()[0x3000])
+	}
+}
+
+func TestRunDoesNotResumeAHaltedRestoredVM(t *testing.T) {
+	haltInstr := uint16(opTRAP)<<12 | trapHALT
+	v := New()
+	if err := v.LoadImage(image(0x3000, haltInstr)); err != nil {
+		t.Fatalf("LoadImage: %v", err)
+	}
+	if err := v.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if v.Running() {
+		t.Fatalf("precondition failed: VM should have halted")
+	}
+
+	var snap bytes.Buffer
+	if err := v.SaveState(&snap, "halted"); err != nil {
+		t.Fatalf("SaveState: %v", err)
+	}
+
+	restored := New()
+	if err := restored.LoadState(bytes.NewReader(snap.Bytes())); err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if restored.Running() {
+		t.Fatalf("expected restored VM to still report halted")
+	}
+
+	if err := restored.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if restored.Running() {
+		t.Fatalf("Run should not have resumed a VM restored in the halted state")
+	}
+}
@@ -0,0 +1,540 @@
+// Package vm implements the LC-3 virtual machine as a self-contained
+// VM type: its own memory, registers and I/O, instead of the
+// package-level globals the emulator started out with. That makes it
+// possible to run several VMs in one process, embed one in a test
+// without touching the real terminal, and plug the debugger, the
+// tracer and the assembler's debug info into it through the same
+// surface the CLI uses.
+package vm
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// MemoryMax is the LC-3's full 16-bit address space.
+const MemoryMax = 1 << 16
+
+const (
+	mrKBSR = 0xFE00 /* keyboard status */
+	mrKBDR = 0xFE02 /* keyboard data */
+)
+
+// Registers
+const (
+	rR0 = iota
+	rR1
+	rR2
+	rR3
+	rR4
+	rR5
+	rR6
+	rR7
+	rPC // program counter
+	rCOND
+	rCOUNT
+)
+
+// Instructions
+const (
+	opBR = iota
+	opADD
+	opLD
+	opST
+	opJSR
+	opAND
+	opLDR
+	opSTR
+	opRTI
+	opNOT
+	opLDI
+	opSTI
+	opJMP
+	opRES
+	opLEA
+	opTRAP
+)
+
+// Condition flags
+const (
+	flPOS = 1 << 0
+	flZRO = 1 << 1
+	flNEG = 1 << 2
+)
+
+// Trap codes
+const (
+	trapGETC  uint16 = 0x20
+	trapOUT   uint16 = 0x21
+	trapPUTS  uint16 = 0x22
+	trapIN    uint16 = 0x23
+	trapPUTSP uint16 = 0x24
+	trapHALT  uint16 = 0x25
+)
+
+// Event is one traced execution step: a fetch about to be dispatched,
+// or a memory/trap side effect that happened while servicing one.
+type Event struct {
+	PC       uint16
+	Category string // "fetch", "mem", "trap", or "io"
+	Opcode   string
+	Operands string
+	Detail   string
+}
+
+// Hook receives every traced Event. A fetch event always fires before
+// that instruction is dispatched, which is what lets a debugger
+// consult its breakpoint set and step flag ahead of execution.
+type Hook func(Event)
+
+var opcodeNames = [16]string{
+	"BR", "ADD", "LD", "ST", "JSR", "AND", "LDR", "STR",
+	"RTI", "NOT", "LDI", "STI", "JMP", "RES", "LEA", "TRAP",
+}
+
+// VM is one LC-3 machine: its memory, registers and I/O streams.
+type VM struct {
+	memory  []uint16
+	reg     [rCOUNT]uint16
+	running bool
+
+	// primed is true once the VM's initial running/PC state has been
+	// established, either by Run's first call or by restoring a
+	// snapshot via LoadState. It keeps Run's one-time defaulting
+	// (running=true, PC=pcStart if zero) from clobbering state a
+	// LoadState call just restored, including a deliberately-halted
+	// VM or a legitimately restored PC of 0.
+	primed bool
+
+	stdin  *bufio.Reader
+	stdout io.Writer
+
+	traceHook  Hook
+	clockLimit uint64
+	executed   uint64
+}
+
+// Option configures a VM at construction time.
+type Option func(*VM)
+
+// WithMemorySize overrides the addressable memory size. Defaults to
+// MemoryMax (the LC-3's full 64K words); mainly useful for tests that
+// want a smaller, cheaper VM.
+func WithMemorySize(words int) Option {
+	return func(v *VM) { v.memory = make([]uint16, words) }
+}
+
+// WithStdin sets the reader TRAP GETC/IN and the keyboard MMIO
+// registers read from. Defaults to no input available.
+func WithStdin(r io.Reader) Option {
+	return func(v *VM) { v.stdin = bufio.NewReader(r) }
+}
+
+// WithStdout sets the writer TRAP OUT/PUTS/PUTSP write to. Defaults
+// to io.Discard.
+func WithStdout(w io.Writer) Option {
+	return func(v *VM) { v.stdout = w }
+}
+
+// WithTraceHook installs a hook called with every traced Event: a
+// "fetch" event right before each instruction executes, plus "mem",
+// "trap" and "io" events as that instruction runs.
+func WithTraceHook(h Hook) Option {
+	return func(v *VM) { v.traceHook = h }
+}
+
+// WithClockLimit stops Run once maxInstr instructions have executed,
+// even if the program never HALTs. A limit of 0 (the default) means
+// unbounded.
+func WithClockLimit(maxInstr uint64) Option {
+	return func(v *VM) { v.clockLimit = maxInstr }
+}
+
+// New creates a VM ready to load an image into.
+func New(opts ...Option) *VM {
+	v := &VM{
+		memory: make([]uint16, MemoryMax),
+		stdin:  bufio.NewReader(new(nullReader)),
+		stdout: io.Discard,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	v.reg[rCOND] = flZRO
+	return v
+}
+
+type nullReader struct{}
+
+func (*nullReader) Read([]byte) (int, error) { return 0, io.EOF }
+
+// Memory exposes the VM's memory for the debugger and disassembler to
+// operate on directly.
+func (v *VM) Memory() []uint16 { return v.memory }
+
+// Registers exposes the VM's register file (R0-R7, PC, COND in that
+// order) for the debugger to operate on directly.
+func (v *VM) Registers() *[10]uint16 { return &v.reg }
+
+// Running reports whether the VM would still execute another Step.
+func (v *VM) Running() bool { return v.running }
+
+// LoadImage reads a big-endian LC-3 object file: an origin word
+// followed by the program words, which are copied into memory
+// starting at that origin.
+func (v *VM) LoadImage(r io.Reader) error {
+	var origin uint16
+	if err := binary.Read(r, binary.BigEndian, &origin); err != nil {
+		return fmt.Errorf("vm: failed to read origin: %w", err)
+	}
+	if int(origin) > len(v.memory) {
+		return fmt.Errorf("vm: image origin 0x%04X exceeds memory size %d", origin, len(v.memory))
+	}
+
+	maxWords := len(v.memory) - int(origin)
+	words := make([]uint16, 0, maxWords)
+	for {
+		var word uint16
+		if err := binary.Read(r, binary.BigEndian, &word); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("vm: failed to read image: %w", err)
+		}
+		words = append(words, word)
+	}
+
+	copy(v.memory[origin:], words)
+	return nil
+}
+
+// Run executes instructions until the program halts, ctx is
+// cancelled, or the configured clock limit is reached. The first time
+// Run is called on a VM that hasn't had its state restored via
+// LoadState, it defaults running to true and PC to pcStart if the PC
+// is still zero; a restored VM's running flag and PC are left exactly
+// as LoadState set them, so a halted snapshot stays halted.
+func (v *VM) Run(ctx context.Context) error {
+	if !v.primed {
+		v.primed = true
+		v.running = true
+		const pcStart = 0x3000
+		if v.reg[rPC] == 0 {
+			v.reg[rPC] = pcStart
+		}
+	}
+
+	for v.running {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if err := v.Step(); err != nil {
+			return err
+		}
+		if v.clockLimit != 0 && v.executed >= v.clockLimit {
+			break
+		}
+	}
+	return nil
+}
+
+// Step executes exactly one fetch-decode-execute cycle.
+func (v *VM) Step() error {
+	pc := v.reg[rPC]
+	instr := v.memRead(pc)
+	op := instr >> 12
+
+	// Fire the trace hook (and, transitively, the debugger) while PC
+	// still points at the instruction we're about to execute, not the
+	// next one — callers that inspect live register state (debugger
+	// "regs", "disasm", "back") expect to see the paused-at PC.
+	if v.traceHook != nil {
+		v.traceHook(Event{
+			PC: pc, Category: "fetch",
+			Opcode: opcodeNames[op], Operands: fmt.Sprintf("0x%04X", instr&0xFFF),
+		})
+	}
+
+	v.reg[rPC]++
+	v.executed++
+
+	switch op {
+	case opADD:
+		v.add(instr)
+	case opAND:
+		v.and(instr)
+	case opNOT:
+		v.not(instr)
+	case opBR:
+		v.br(instr)
+	case opJMP:
+		v.jmp(instr)
+	case opJSR:
+		v.jsr(instr)
+	case opLD:
+		v.ld(instr)
+	case opLDI:
+		v.ldi(instr)
+	case opLDR:
+		v.ldr(instr)
+	case opLEA:
+		v.lea(instr)
+	case opST:
+		v.st(instr)
+	case opSTI:
+		v.sti(instr)
+	case opSTR:
+		v.str(instr)
+	case opTRAP:
+		v.trap(instr)
+	case opRES, opRTI:
+		// unused opcodes
+	default:
+		v.running = false
+		return fmt.Errorf("vm: illegal opcode 0x%X at 0x%04X", op, pc)
+	}
+	return nil
+}
+
+func signExtend(x uint16, bitCount int) uint16 {
+	if (x>>(bitCount-1))&1 == 1 {
+		x |= 0xFFFF << bitCount
+	}
+	return x
+}
+
+func (v *VM) updateFlags(r uint16) {
+	switch {
+	case v.reg[r] == 0:
+		v.reg[rCOND] = flZRO
+	case v.reg[r]>>15 == 1:
+		v.reg[rCOND] = flNEG
+	default:
+		v.reg[rCOND] = flPOS
+	}
+}
+
+func (v *VM) add(instr uint16) {
+	r0 := (instr >> 9) & 0x7
+	r1 := (instr >> 6) & 0x7
+	if (instr>>5)&0x1 == 1 {
+		imm5 := signExtend(instr&0x1F, 5)
+		v.reg[r0] = v.reg[r1] + imm5
+	} else {
+		r2 := instr & 0x7
+		v.reg[r0] = v.reg[r1] + v.reg[r2]
+	}
+	v.updateFlags(r0)
+}
+
+func (v *VM) and(instr uint16) {
+	r0 := (instr >> 9) & 0x7
+	r1 := (instr >> 6) & 0x7
+	if (instr>>5)&0x1 == 1 {
+		imm5 := signExtend(instr&0x1F, 5)
+		v.reg[r0] = v.reg[r1] & imm5
+	} else {
+		r2 := instr & 0x7
+		v.reg[r0] = v.reg[r1] & v.reg[r2]
+	}
+	v.updateFlags(r0)
+}
+
+func (v *VM) not(instr uint16) {
+	r0 := (instr >> 9) & 0x7
+	r1 := (instr >> 6) & 0x7
+	v.reg[r0] = ^v.reg[r1]
+	v.updateFlags(r0)
+}
+
+func (v *VM) br(instr uint16) {
+	pcOffset := signExtend(instr&0x1FF, 9)
+	condFlag := (instr >> 9) & 0x7
+	if condFlag&v.reg[rCOND] != 0 {
+		v.reg[rPC] += pcOffset
+	}
+}
+
+func (v *VM) jmp(instr uint16) {
+	r1 := (instr >> 6) & 0x7
+	v.reg[rPC] = v.reg[r1]
+}
+
+func (v *VM) jsr(instr uint16) {
+	longFlag := (instr >> 11) & 1
+	v.reg[rR7] = v.reg[rPC]
+	if longFlag == 1 {
+		longPcOffset := signExtend(instr&0x7FF, 11)
+		v.reg[rPC] += longPcOffset
+	} else {
+		r1 := (instr >> 6) & 0x7
+		v.reg[rPC] = v.reg[r1]
+	}
+}
+
+func (v *VM) ld(instr uint16) {
+	r0 := (instr >> 9) & 0x7
+	pcOffset := signExtend(instr&0x1FF, 9)
+	v.reg[r0] = v.memRead(v.reg[rPC] + pcOffset)
+	v.updateFlags(r0)
+}
+
+func (v *VM) ldi(instr uint16) {
+	r0 := (instr >> 9) & 0x7
+	pcOffset := signExtend(instr&0x1FF, 9)
+	v.reg[r0] = v.memRead(v.memRead(v.reg[rPC] + pcOffset))
+	v.updateFlags(r0)
+}
+
+func (v *VM) ldr(instr uint16) {
+	r0 := (instr >> 9) & 0x7
+	r1 := (instr >> 6) & 0x7
+	offset := signExtend(instr&0x3F, 6)
+	v.reg[r0] = v.memRead(v.reg[r1] + offset)
+	v.updateFlags(r0)
+}
+
+func (v *VM) lea(instr uint16) {
+	r0 := (instr >> 9) & 0x7
+	pcOffset := signExtend(instr&0x1FF, 9)
+	v.reg[r0] = v.reg[rPC] + pcOffset
+	v.updateFlags(r0)
+}
+
+func (v *VM) st(instr uint16) {
+	r0 := (instr >> 9) & 0x7
+	pcOffset := signExtend(instr&0x1FF, 9)
+	v.memWrite(v.reg[rPC]+pcOffset, v.reg[r0])
+}
+
+func (v *VM) sti(instr uint16) {
+	r0 := (instr >> 9) & 0x7
+	pcOffset := signExtend(instr&0x1FF, 9)
+	v.memWrite(v.memRead(v.reg[rPC]+pcOffset), v.reg[r0])
+}
+
+func (v *VM) str(instr uint16) {
+	r0 := (instr >> 9) & 0x7
+	r1 := (instr >> 6) & 0x7
+	offset := signExtend(instr&0x3F, 6)
+	v.memWrite(v.reg[r1]+offset, v.reg[r0])
+}
+
+func (v *VM) trap(instr uint16) {
+	v.reg[rR7] = v.reg[rPC]
+
+	if v.traceHook != nil {
+		v.traceHook(Event{PC: v.reg[rPC], Category: "trap", Detail: fmt.Sprintf("vector 0x%02X", instr&0xFF)})
+	}
+
+	switch instr & 0xFF {
+	case trapGETC:
+		v.trapGetc()
+	case trapOUT:
+		v.trapOut()
+	case trapPUTS:
+		v.trapPuts()
+	case trapIN:
+		v.trapIn()
+	case trapPUTSP:
+		v.trapPutsp()
+	case trapHALT:
+		v.trapHalt()
+	}
+}
+
+// ioEvent fires an "io" trace event for an I/O trap, carrying the trap
+// name and the value of R0 in Detail (the character read or written,
+// or for PUTS/PUTSP the string's address).
+func (v *VM) ioEvent(op string, val uint16) {
+	if v.traceHook != nil {
+		v.traceHook(Event{PC: v.reg[rPC], Category: "io", Detail: fmt.Sprintf("%s R0 = 0x%04X", op, val)})
+	}
+}
+
+func (v *VM) trapGetc() {
+	v.reg[rR0] = v.getChar()
+	v.updateFlags(rR0)
+	v.ioEvent("GETC ->", v.reg[rR0])
+}
+
+func (v *VM) trapOut() {
+	fmt.Fprintf(v.stdout, "%c", rune(v.reg[rR0]))
+	v.ioEvent("OUT <-", v.reg[rR0])
+}
+
+func (v *VM) trapIn() {
+	fmt.Fprint(v.stdout, "Enter a character: ")
+	char := v.getChar()
+	fmt.Fprintf(v.stdout, "%c", char)
+	v.reg[rR0] = char
+	v.updateFlags(rR0)
+	v.ioEvent("IN ->", v.reg[rR0])
+}
+
+func (v *VM) trapPuts() {
+	for _, word := range v.memory[v.reg[rR0]:] {
+		if word == 0 {
+			break
+		}
+		fmt.Fprintf(v.stdout, "%c", word)
+	}
+	fmt.Fprintln(v.stdout)
+	v.ioEvent("PUTS <-", v.reg[rR0])
+}
+
+func (v *VM) trapPutsp() {
+	for _, word := range v.memory[v.reg[rR0]:] {
+		if word == 0 {
+			break
+		}
+		lo := word & 0xFF
+		fmt.Fprintf(v.stdout, "%c", lo)
+		if hi := word >> 8; hi != 0 {
+			fmt.Fprintf(v.stdout, "%c", hi)
+		}
+	}
+	v.ioEvent("PUTSP <-", v.reg[rR0])
+}
+
+func (v *VM) trapHalt() {
+	fmt.Fprint(v.stdout, "HALT")
+	v.running = false
+}
+
+func (v *VM) getChar() uint16 {
+	r, _, err := v.stdin.ReadRune()
+	if err != nil {
+		return 0
+	}
+	return uint16(r)
+}
+
+func (v *VM) checkKey() bool {
+	_, err := v.stdin.Peek(1)
+	return err == nil
+}
+
+func (v *VM) memRead(address uint16) uint16 {
+	if address == mrKBSR {
+		if v.checkKey() {
+			v.memory[mrKBSR] = 1 << 15
+			v.memory[mrKBDR] = v.getChar()
+		} else {
+			v.memory[mrKBSR] = 0
+		}
+	}
+	return v.memory[address]
+}
+
+func (v *VM) memWrite(address, val uint16) {
+	if v.traceHook != nil {
+		v.traceHook(Event{PC: v.reg[rPC], Category: "mem", Detail: fmt.Sprintf("write 0x%04X <- 0x%04X", address, val)})
+	}
+	v.memory[address] = val
+}
@@ -0,0 +1,93 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+)
+
+// image builds a minimal object file: origin word, then program words.
+func image(origin uint16, words ...uint16) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, origin)
+	for _, w := range words {
+		binary.Write(buf, binary.BigEndian, w)
+	}
+	return buf
+}
+
+func TestStepHookSeesPreIncrementPC(t *testing.T) {
+	// ADD R0, R0, #0 at 0x3000, then HALT.
+	addInstr := uint16(opADD)<<12 | 1<<5
+	haltInstr := uint16(opTRAP)<<12 | trapHALT
+
+	var hookPCs []uint16
+	v := New(WithTraceHook(func(ev Event) {
+		if ev.Category == "fetch" {
+			hookPCs = append(hookPCs, ev.PC)
+		}
+	}))
+	if err := v.LoadImage(image(0x3000, addInstr, haltInstr)); err != nil {
+		t.Fatalf("LoadImage: %v", err)
+	}
+	v.reg[rPC] = 0x3000
+
+	if err := v.Step(); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	if len(hookPCs) != 1 || hookPCs[0] != 0x3000 {
+		t.Fatalf("expected hook to fire with PC 0x3000, got %v", hookPCs)
+	}
+	if v.reg[rPC] != 0x3001 {
+		t.Fatalf("expected PC to advance to 0x3001 after Step, got 0x%04X", v.reg[rPC])
+	}
+}
+
+func TestTrapOutFiresIOEvent(t *testing.T) {
+	outInstr := uint16(opTRAP)<<12 | trapOUT
+	haltInstr := uint16(opTRAP)<<12 | trapHALT
+
+	var ioEvents []Event
+	v := New(WithTraceHook(func(ev Event) {
+		if ev.Category == "io" {
+			ioEvents = append(ioEvents, ev)
+		}
+	}))
+	if err := v.LoadImage(image(0x3000, outInstr, haltInstr)); err != nil {
+		t.Fatalf("LoadImage: %v", err)
+	}
+	v.reg[rR0] = 'A'
+
+	if err := v.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(ioEvents) != 1 {
+		t.Fatalf("expected exactly one io event from TRAP OUT, got %d", len(ioEvents))
+	}
+	if ioEvents[0].Detail == "" {
+		t.Fatalf("expected io event to describe the register mutation, got empty Detail")
+	}
+}
+
+func TestLoadImageRejectsOriginBeyondMemorySize(t *testing.T) {
+	v := New(WithMemorySize(100))
+	err := v.LoadImage(image(5000, 0x1234))
+	if err == nil {
+		t.Fatalf("expected an error for an origin beyond the configured memory size, got nil")
+	}
+}
+
+func TestRunHaltsOnTrapHalt(t *testing.T) {
+	haltInstr := uint16(opTRAP)<<12 | trapHALT
+	v := New()
+	if err := v.LoadImage(image(0x3000, haltInstr)); err != nil {
+		t.Fatalf("LoadImage: %v", err)
+	}
+	if err := v.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if v.Running() {
+		t.Fatalf("expected VM to have stopped running after HALT")
+	}
+}